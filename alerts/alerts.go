@@ -0,0 +1,98 @@
+// Package alerts dispatches notifications when a polled URL's state
+// transitions between OK and ERROR, routing each transition to one or more
+// configurable Notifier sinks.
+package alerts
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// notifyTimeout bounds how long a single Notify call may run. Without
+	// it, a hung webhook endpoint, SMTP server, or exec command would
+	// block Run indefinitely — and, since transitions is bounded, that in
+	// turn blocks StateMonitor and every Poller feeding it.
+	notifyTimeout = 10 * time.Second
+
+	// notifyWorkers bounds how many Notify calls Run allows to run at
+	// once, so a burst of transitions can't spawn unbounded goroutines.
+	notifyWorkers = 8
+)
+
+// Class identifies which side of a transition a poll result fell on.
+type Class string
+
+const (
+	OK      Class = "OK"
+	Error   Class = "ERROR"
+	Unknown Class = ""
+)
+
+// Transition describes a single state change observed for a URL.
+type Transition struct {
+	URL               string
+	From              Class
+	To                Class
+	ConsecutiveErrors int
+	Message           string
+	Time              time.Time
+}
+
+// Notifier delivers a Transition to some external sink. Implementations
+// must respect ctx's deadline so a slow or unresponsive sink can't block
+// the Dispatcher that called them.
+type Notifier interface {
+	Notify(ctx context.Context, t Transition) error
+}
+
+// Dispatcher fans transitions out to the Notifiers registered for the
+// transition's URL. It does no deduplication of its own and delivers
+// every Transition it receives: keeping a flapping site from spamming its
+// sinks is left to StateMonitor in the main package, which only sends an
+// actual class change or a sustained-error escalation here.
+type Dispatcher struct {
+	mu     sync.Mutex
+	routes map[string][]Notifier // url -> notifiers
+}
+
+// NewDispatcher returns a Dispatcher with no routes registered.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{routes: make(map[string][]Notifier)}
+}
+
+// Route registers notifiers to receive transitions for url.
+func (d *Dispatcher) Route(url string, notifiers ...Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.routes[url] = append(d.routes[url], notifiers...)
+}
+
+// Run consumes transitions until in is closed, delivering each to the
+// notifiers routed for its URL. Notify calls run concurrently, each
+// bounded by notifyTimeout and the whole batch by notifyWorkers, so one
+// slow sink can't delay delivery to another or stall the next Transition.
+// It is meant to be run in its own goroutine.
+func (d *Dispatcher) Run(in <-chan Transition) {
+	sem := make(chan struct{}, notifyWorkers)
+	for t := range in {
+		d.mu.Lock()
+		notifiers := d.routes[t.URL]
+		d.mu.Unlock()
+		for _, n := range notifiers {
+			sem <- struct{}{}
+			go func(n Notifier, t Transition) {
+				defer func() { <-sem }()
+
+				ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+				defer cancel()
+
+				if err := n.Notify(ctx, t); err != nil {
+					log.Println("alerts: notify failed for", t.URL, err)
+				}
+			}(n, t)
+		}
+	}
+}
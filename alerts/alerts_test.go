@@ -0,0 +1,114 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingNotifier blocks until ctx is done (or release is closed),
+// simulating a hung sink.
+type blockingNotifier struct {
+	release chan struct{}
+	calls   int32
+}
+
+func (b *blockingNotifier) Notify(ctx context.Context, t Transition) error {
+	atomic.AddInt32(&b.calls, 1)
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// countingNotifier records every Transition it receives.
+type countingNotifier struct {
+	mu   sync.Mutex
+	seen []Transition
+	done chan struct{}
+}
+
+func newCountingNotifier(want int) *countingNotifier {
+	return &countingNotifier{done: make(chan struct{}, want)}
+}
+
+func (c *countingNotifier) Notify(ctx context.Context, t Transition) error {
+	c.mu.Lock()
+	c.seen = append(c.seen, t)
+	c.mu.Unlock()
+	c.done <- struct{}{}
+	return nil
+}
+
+func TestDispatcherRunDeliversToRoutedNotifiers(t *testing.T) {
+	d := NewDispatcher()
+	n := newCountingNotifier(1)
+	d.Route("http://example.com", n)
+
+	in := make(chan Transition, 1)
+	go d.Run(in)
+	in <- Transition{URL: "http://example.com", From: OK, To: Error}
+	close(in)
+
+	select {
+	case <-n.done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Notify")
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.seen) != 1 || n.seen[0].URL != "http://example.com" {
+		t.Fatalf("unexpected deliveries: %+v", n.seen)
+	}
+}
+
+// TestDispatcherRunHungNotifierDoesNotBlockOthers verifies that a
+// Notifier which never returns doesn't prevent a Notifier routed for a
+// different URL from being called.
+func TestDispatcherRunHungNotifierDoesNotBlockOthers(t *testing.T) {
+	d := NewDispatcher()
+	hung := &blockingNotifier{release: make(chan struct{})} // never released in this test
+	fast := newCountingNotifier(1)
+	d.Route("http://hung.example", hung)
+	d.Route("http://fast.example", fast)
+
+	in := make(chan Transition, 2)
+	go d.Run(in)
+	in <- Transition{URL: "http://hung.example", From: OK, To: Error}
+	in <- Transition{URL: "http://fast.example", From: OK, To: Error}
+	close(in)
+
+	select {
+	case <-fast.done:
+	case <-time.After(time.Second):
+		t.Fatal("hung notifier blocked delivery to an unrelated notifier")
+	}
+}
+
+func TestWebhookNotifierNotifyRespectsContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	w := &WebhookNotifier{URL: srv.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := w.Notify(ctx, Transition{URL: "http://example.com"})
+	if err == nil {
+		t.Fatal("expected an error from a webhook slower than the context deadline")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a context deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package alerts
+
+import (
+	"context"
+	"os/exec"
+)
+
+// ExecNotifier delivers a Transition by invoking an external command with
+// the formatted alert message appended as its final argument.
+type ExecNotifier struct {
+	Command string
+	Args    []string
+}
+
+// Notify implements Notifier.
+func (e *ExecNotifier) Notify(ctx context.Context, t Transition) error {
+	args := append(append([]string{}, e.Args...), formatMessage(t))
+	return exec.CommandContext(ctx, e.Command, args...).Run()
+}
@@ -0,0 +1,49 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier delivers a Transition by email via net/smtp.
+type SMTPNotifier struct {
+	Addr string // host:port of the SMTP server
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Notify implements Notifier. net/smtp has no context-aware API, so
+// SendMail runs in its own goroutine; if ctx expires first, Notify
+// returns without waiting for it (the goroutine is left to finish, or
+// hang, on its own).
+func (s *SMTPNotifier) Notify(ctx context.Context, t Transition) error {
+	subject := fmt.Sprintf("urlpoll alert: %s is %s", t.URL, t.To)
+	body := formatMessage(t)
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, body)
+
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(s.Addr, s.Auth, s.From, s.To, []byte(msg)) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("alerts: smtp send to %s: %w", s.Addr, ctx.Err())
+	}
+}
+
+// NewSMTPAuth builds PlainAuth for username/password, or nil if username
+// is empty (for servers that don't require authentication).
+func NewSMTPAuth(addr, username, password string) smtp.Auth {
+	if username == "" {
+		return nil
+	}
+	host := addr
+	if i := strings.IndexByte(addr, ':'); i >= 0 {
+		host = addr[:i]
+	}
+	return smtp.PlainAuth("", username, password, host)
+}
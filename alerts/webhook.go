@@ -0,0 +1,58 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier posts a Slack-compatible JSON payload to a webhook URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // if nil, http.DefaultClient is used
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// Notify implements Notifier.
+func (w *WebhookNotifier) Notify(ctx context.Context, t Transition) error {
+	body, err := json.Marshal(slackPayload{Text: formatMessage(t)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alerts: webhook %s returned %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// formatMessage renders a Transition as a human-readable one-liner shared
+// by the webhook and SMTP notifiers.
+func formatMessage(t Transition) string {
+	if t.Message != "" {
+		return t.Message
+	}
+	return fmt.Sprintf("%s: %s -> %s (consecutive errors: %d)", t.URL, t.From, t.To, t.ConsecutiveErrors)
+}
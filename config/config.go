@@ -0,0 +1,124 @@
+// Package config loads the urlpoll configuration file, which replaces the
+// original plain-text URL list with a JSON document describing each URL's
+// poll interval, expected status, timeout, and alert routing.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Duration wraps time.Duration so it can be unmarshaled from the
+// human-readable strings accepted by time.ParseDuration (e.g. "30s").
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("config: invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// URLConfig describes a single URL to poll.
+type URLConfig struct {
+	URL            string   `json:"url"`
+	Method         string   `json:"method,omitempty"`
+	ExpectedStatus string   `json:"expectedStatus,omitempty"`
+	Interval       Duration `json:"interval,omitempty"`
+	Timeout        Duration `json:"timeout,omitempty"`
+	Alerts         []string `json:"alerts,omitempty"` // names of AlertConfig entries to notify
+}
+
+// WebhookConfig configures a Slack-compatible JSON webhook sink.
+type WebhookConfig struct {
+	URL string `json:"url"`
+}
+
+// SMTPConfig configures an SMTP mail sink.
+type SMTPConfig struct {
+	Addr     string   `json:"addr"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+}
+
+// ExecConfig configures a generic exec hook sink. The alert message is
+// appended as the final argument to Command.
+type ExecConfig struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// AlertConfig names and configures one alert sink. Exactly one of
+// Webhook, SMTP, or Exec should be set, matching Type.
+type AlertConfig struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"` // "webhook", "smtp", or "exec"
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	SMTP    *SMTPConfig    `json:"smtp,omitempty"`
+	Exec    *ExecConfig    `json:"exec,omitempty"`
+}
+
+// Config is the top-level urlpoll configuration document.
+type Config struct {
+	URLs   []URLConfig   `json:"urls"`
+	Alerts []AlertConfig `json:"alerts,omitempty"`
+	// SustainedErrorIntervals is the number of consecutive failed polls
+	// after which a sustained-error alert is re-raised. Zero disables it.
+	SustainedErrorIntervals int `json:"sustainedErrorIntervals,omitempty"`
+}
+
+// Load reads and parses the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read %s: %w", path, err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("config: failed to parse %s: %w", path, err)
+	}
+	if len(c.URLs) == 0 {
+		return nil, fmt.Errorf("config: %s declares no urls", path)
+	}
+	for _, a := range c.Alerts {
+		if err := a.validate(); err != nil {
+			return nil, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	return &c, nil
+}
+
+// validate reports an error if a's Type doesn't have a matching,
+// non-nil sub-config, e.g. Type "webhook" with Webhook left unset. This
+// catches an easy hand-edit mistake, such as a typo'd field name, at
+// load time rather than as a nil-pointer panic when the alert fires.
+func (a AlertConfig) validate() error {
+	switch a.Type {
+	case "webhook":
+		if a.Webhook == nil {
+			return fmt.Errorf("alert %q is type %q but declares no webhook config", a.Name, a.Type)
+		}
+	case "smtp":
+		if a.SMTP == nil {
+			return fmt.Errorf("alert %q is type %q but declares no smtp config", a.Name, a.Type)
+		}
+	case "exec":
+		if a.Exec == nil {
+			return fmt.Errorf("alert %q is type %q but declares no exec config", a.Name, a.Type)
+		}
+	default:
+		return fmt.Errorf("alert %q has unknown type %q", a.Name, a.Type)
+	}
+	return nil
+}
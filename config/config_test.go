@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		json    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{`"30s"`, 30 * time.Second, false},
+		{`"5m"`, 5 * time.Minute, false},
+		{`"not-a-duration"`, 0, true},
+		{`123`, 0, true}, // must be a string, not a number
+	}
+	for _, c := range cases {
+		var d Duration
+		err := d.UnmarshalJSON([]byte(c.json))
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("UnmarshalJSON(%s): expected error, got none", c.json)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnmarshalJSON(%s): %v", c.json, err)
+			continue
+		}
+		if time.Duration(d) != c.want {
+			t.Errorf("UnmarshalJSON(%s) = %v, want %v", c.json, time.Duration(d), c.want)
+		}
+	}
+}
+
+func TestLoadRejectsAlertMissingMatchingConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeConfig(t, path, Config{
+		URLs:   []URLConfig{{URL: "http://example.com"}},
+		Alerts: []AlertConfig{{Name: "ops", Type: "webhook"}}, // Webhook left nil
+	})
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load: expected error for alert with no matching sub-config, got none")
+	}
+}
+
+func TestLoadAcceptsWellFormedAlert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeConfig(t, path, Config{
+		URLs: []URLConfig{{URL: "http://example.com"}},
+		Alerts: []AlertConfig{
+			{Name: "ops", Type: "webhook", Webhook: &WebhookConfig{URL: "http://hooks.example.com"}},
+		},
+	})
+
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+}
+
+func writeConfig(t *testing.T, path string, c Config) {
+	t.Helper()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package crawl turns a successful HTML poll into a shallow site-monitor
+// crawl: it parses <a href> links, resolves them against the page they
+// came from, and reports which ones are new and worth enqueuing as their
+// own Resources, up to a configured depth.
+package crawl
+
+import (
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Link is a newly discovered URL to poll, one hop deeper than Parent.
+type Link struct {
+	URL    string
+	Depth  int
+	Parent string
+}
+
+// visit is a request to the Crawler's owning goroutine to atomically
+// check-and-mark a URL as visited.
+type visit struct {
+	url   string
+	reply chan bool
+}
+
+// Crawler tracks which URLs have already been discovered (so the same
+// link isn't enqueued twice) and rate-limits discovery per host. The
+// visited set is owned by a single goroutine, following the same
+// share-memory-by-communicating pattern as StateMonitor, rather than
+// being guarded by a mutex.
+type Crawler struct {
+	maxDepth int
+	visits   chan visit
+	releases chan string
+	limiter  *hostRateLimiter
+}
+
+// NewCrawler returns a Crawler that discovers links up to maxDepth hops
+// from their origin, visiting any one host no more than once per
+// perHostInterval.
+func NewCrawler(maxDepth int, perHostInterval time.Duration) *Crawler {
+	c := &Crawler{
+		maxDepth: maxDepth,
+		visits:   make(chan visit),
+		releases: make(chan string),
+		limiter:  newHostRateLimiter(perHostInterval),
+	}
+	go c.run()
+	return c
+}
+
+func (c *Crawler) run() {
+	seen := make(map[string]bool)
+	for {
+		select {
+		case v := <-c.visits:
+			if seen[v.url] {
+				v.reply <- false
+				continue
+			}
+			seen[v.url] = true
+			v.reply <- true
+		case url := <-c.releases:
+			delete(seen, url)
+		}
+	}
+}
+
+// claim reports whether url has not been seen before, marking it seen.
+func (c *Crawler) claim(url string) bool {
+	reply := make(chan bool)
+	c.visits <- visit{url: url, reply: reply}
+	return <-reply
+}
+
+// Seed marks urls as already seen, without returning any Links for them.
+// Callers should seed the top-level configured URLs before crawling
+// starts, since those are sent straight to the poll queue rather than
+// going through claim, and would otherwise be "discovered" again (and
+// polled a second time, with their own independent schedule) the first
+// time a page links back to one of them.
+func (c *Crawler) Seed(urls []string) {
+	for _, u := range urls {
+		c.claim(u)
+	}
+}
+
+// release forgets that url was claimed, so a later Discover call is free
+// to claim it again. It is used to back out a claim that turned out not
+// to be enqueued, e.g. because the host's rate limit hadn't reopened yet.
+func (c *Crawler) release(url string) {
+	c.releases <- url
+}
+
+// Discover parses body as the HTML of baseURL (at parentDepth) and
+// returns the links it contains that are both new and within maxDepth.
+func (c *Crawler) Discover(baseURL string, parentDepth int, body io.Reader) ([]Link, error) {
+	if parentDepth >= c.maxDepth {
+		return nil, nil
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []Link
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				ref, err := url.Parse(attr.Val)
+				if err != nil {
+					continue
+				}
+				resolved := base.ResolveReference(ref)
+				if resolved.Scheme != "http" && resolved.Scheme != "https" {
+					// mailto:, tel:, javascript:, etc. aren't
+					// pollable; http.Client.Do would just reject
+					// them with "unsupported protocol scheme" on
+					// every retry forever.
+					continue
+				}
+				resolved.Fragment = ""
+				target := resolved.String()
+
+				if !c.claim(target) {
+					continue
+				}
+				if !c.limiter.allow(resolved.Host) {
+					// Not actually enqueuing this link: release the
+					// claim so it isn't burned forever and can be
+					// retried once the host's rate limit reopens.
+					c.release(target)
+					continue
+				}
+				links = append(links, Link{URL: target, Depth: parentDepth + 1, Parent: baseURL})
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return links, nil
+}
+
+// hostRateLimiter allows at most one discovery per host per interval, so
+// a single page full of links to the same host doesn't all get crawled
+// in the same instant.
+type hostRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func newHostRateLimiter(interval time.Duration) *hostRateLimiter {
+	return &hostRateLimiter{interval: interval, last: make(map[string]time.Time)}
+}
+
+func (h *hostRateLimiter) allow(host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := h.last[host]; ok && now.Sub(last) < h.interval {
+		return false
+	}
+	h.last[host] = now
+	return true
+}
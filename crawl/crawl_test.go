@@ -0,0 +1,107 @@
+package crawl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiscoverDedupesLinks(t *testing.T) {
+	c := NewCrawler(5, 0)
+	body := `<html><body><a href="/a">a</a><a href="/a">again</a><a href="/b">b</a></body></html>`
+
+	links, err := c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2: %+v", len(links), links)
+	}
+
+	links, err = c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover (second pass): %v", err)
+	}
+	if len(links) != 0 {
+		t.Fatalf("second pass over the same page should discover nothing new, got %+v", links)
+	}
+}
+
+func TestDiscoverRespectsMaxDepth(t *testing.T) {
+	c := NewCrawler(1, time.Hour)
+	body := `<html><body><a href="/a">a</a></body></html>`
+
+	links, err := c.Discover("http://example.com/", 1, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if links != nil {
+		t.Fatalf("expected no links past maxDepth, got %+v", links)
+	}
+}
+
+// TestDiscoverSkipsNonHTTPSchemes verifies that mailto:, tel:, and
+// javascript: hrefs are never claimed or returned, since they can't be
+// polled and would otherwise spawn permanently-failing Resources.
+func TestDiscoverSkipsNonHTTPSchemes(t *testing.T) {
+	c := NewCrawler(5, 0)
+	body := `<html><body>
+		<a href="mailto:hi@example.com">mail</a>
+		<a href="tel:+15555555555">tel</a>
+		<a href="javascript:alert(1)">js</a>
+		<a href="/a">a</a>
+	</body></html>`
+
+	links, err := c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(links) != 1 || links[0].URL != "http://example.com/a" {
+		t.Fatalf("expected only the http link, got %+v", links)
+	}
+}
+
+// TestSeedPreventsRediscoveryOfConfiguredURLs verifies that a URL seeded
+// up front (standing in for the top-level configured URLs, which bypass
+// claim entirely) isn't returned as a newly discovered link when a page
+// links back to it.
+func TestSeedPreventsRediscoveryOfConfiguredURLs(t *testing.T) {
+	c := NewCrawler(5, 0)
+	c.Seed([]string{"http://example.com/"})
+	body := `<html><body><a href="/">home</a><a href="/a">a</a></body></html>`
+
+	links, err := c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(links) != 1 || links[0].URL != "http://example.com/a" {
+		t.Fatalf("expected the seeded URL to be skipped, got %+v", links)
+	}
+}
+
+// TestDiscoverRateLimitedLinkIsRetriedLater verifies that a link deferred
+// by the host rate limiter isn't permanently burned by claim: once the
+// limiter's window reopens, a later Discover call for the same page (e.g.
+// its next poll) must be able to claim and return it.
+func TestDiscoverRateLimitedLinkIsRetriedLater(t *testing.T) {
+	c := NewCrawler(5, 20*time.Millisecond)
+	body := `<html><body><a href="/a">a</a><a href="/b">b</a></body></html>`
+
+	links, err := c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("expected only the first link to pass the rate limiter, got %+v", links)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	links, err = c.Discover("http://example.com/", 0, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Discover (after rate limit window): %v", err)
+	}
+	if len(links) != 1 || links[0].URL != "http://example.com/b" {
+		t.Fatalf("expected the rate-limited link to be retried, got %+v", links)
+	}
+}
@@ -0,0 +1,142 @@
+// Package dashboard renders a live terminal view of urlpoll's polling
+// state: one progress bar per URL showing time-until-next-poll, color
+// coded by last status, plus aggregate counters. It is fed from a
+// broadcast tap on the main package's StateMonitor updates, so it can be
+// swapped in for plain log output without touching the poller logic.
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const barWidth = 30
+
+// Update is a single URL's latest observed state, as reported by main.
+type Update struct {
+	URL        string
+	Status     string
+	StatusCode int
+	Failed     bool
+	ErrCount   int
+	NextPollAt time.Time
+}
+
+// row is the dashboard's retained state for one URL.
+type row struct {
+	lastPollAt time.Time
+	Update
+}
+
+// Dashboard accumulates Updates and renders them to the terminal.
+type Dashboard struct {
+	mu        sync.Mutex
+	rows      map[string]*row
+	startedAt time.Time
+	polls     int64
+}
+
+// New returns an empty Dashboard.
+func New() *Dashboard {
+	return &Dashboard{rows: make(map[string]*row), startedAt: time.Now()}
+}
+
+// Observe records the latest Update for its URL.
+func (d *Dashboard) Observe(u Update) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rows[u.URL] = &row{lastPollAt: time.Now(), Update: u}
+	d.polls++
+}
+
+// Run redraws the dashboard on every tick of refresh until stop is closed.
+func (d *Dashboard) Run(refresh time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.render()
+		}
+	}
+}
+
+func (d *Dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	urls := make([]string, 0, len(d.rows))
+	for u := range d.rows {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	var ok, failing int
+	var b strings.Builder
+	b.WriteString("\x1b[2J\x1b[H") // clear screen, move cursor home
+	for _, url := range urls {
+		r := d.rows[url]
+		if r.Failed {
+			failing++
+		} else {
+			ok++
+		}
+		b.WriteString(renderRow(url, r))
+		b.WriteByte('\n')
+	}
+
+	elapsed := time.Since(d.startedAt).Seconds()
+	var pollsPerSec float64
+	if elapsed > 0 {
+		pollsPerSec = float64(d.polls) / elapsed
+	}
+	fmt.Fprintf(&b, "\ntotal: %d ok, %d failing, %.2f polls/sec\n", ok, failing, pollsPerSec)
+
+	fmt.Print(b.String())
+}
+
+func renderRow(url string, r *row) string {
+	const (
+		green = "\x1b[32m"
+		red   = "\x1b[31m"
+		reset = "\x1b[0m"
+	)
+
+	color := green
+	if r.Failed {
+		color = red
+	}
+
+	remaining := time.Until(r.NextPollAt)
+	total := r.NextPollAt.Sub(r.lastPollAt)
+	frac := 0.0
+	if total > 0 {
+		frac = 1 - float64(remaining)/float64(total)
+	}
+	frac = clamp(frac, 0, 1)
+
+	filled := int(frac * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+
+	status := r.Status
+	if r.ErrCount > 0 {
+		status = fmt.Sprintf("%s (errs: %d)", status, r.ErrCount)
+	}
+
+	return fmt.Sprintf("%s[%s]%s %-40s %s", color, bar, reset, url, status)
+}
+
+func clamp(f, min, max float64) float64 {
+	if f < min {
+		return min
+	}
+	if f > max {
+		return max
+	}
+	return f
+}
@@ -0,0 +1,78 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClamp(t *testing.T) {
+	cases := []struct {
+		f, min, max, want float64
+	}{
+		{0.5, 0, 1, 0.5},
+		{-1, 0, 1, 0},
+		{2, 0, 1, 1},
+		{0, 0, 1, 0},
+		{1, 0, 1, 1},
+	}
+	for _, c := range cases {
+		if got := clamp(c.f, c.min, c.max); got != c.want {
+			t.Errorf("clamp(%v, %v, %v) = %v, want %v", c.f, c.min, c.max, got, c.want)
+		}
+	}
+}
+
+// TestRenderRowFracClamped verifies that a NextPollAt in the past (we're
+// overdue), a poll that has only just started, or a zero-length interval
+// never produces a bar outside [0, barWidth], since renderRow runs frac
+// through clamp before using it to size the fill.
+func TestRenderRowFracClamped(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name       string
+		lastPollAt time.Time
+		nextPollAt time.Time
+	}{
+		{"overdue", now.Add(-time.Minute), now.Add(-time.Second)},
+		{"not yet started", now, now.Add(time.Minute)},
+		{"zero total", now, now},
+	}
+	for _, c := range cases {
+		r := &row{lastPollAt: c.lastPollAt, Update: Update{URL: "http://example.com", NextPollAt: c.nextPollAt}}
+		out := renderRow("http://example.com", r)
+		filled := strings.Count(strings.SplitN(strings.SplitN(out, "[", 2)[1], "]", 2)[0], "=")
+		if filled < 0 || filled > barWidth {
+			t.Errorf("%s: bar fill %d out of [0, %d] range: %q", c.name, filled, barWidth, out)
+		}
+	}
+}
+
+func TestDashboardObserveTracksRowsAndPollCount(t *testing.T) {
+	d := New()
+	d.Observe(Update{URL: "http://a.example.com", Failed: false})
+	d.Observe(Update{URL: "http://b.example.com", Failed: true})
+	d.Observe(Update{URL: "http://a.example.com", Failed: true}) // update, not a new row
+
+	if len(d.rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(d.rows), d.rows)
+	}
+	if d.polls != 3 {
+		t.Fatalf("got %d polls, want 3", d.polls)
+	}
+
+	var ok, failing int
+	for _, r := range d.rows {
+		if r.Failed {
+			failing++
+		} else {
+			ok++
+		}
+	}
+	if ok+failing != len(d.rows) {
+		t.Fatalf("ok (%d) + failing (%d) != rows (%d)", ok, failing, len(d.rows))
+	}
+	if ok != 0 || failing != 2 {
+		t.Fatalf("got ok=%d failing=%d, want ok=0 failing=2 (a.example.com's last Observe was a failure)", ok, failing)
+	}
+}
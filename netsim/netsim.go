@@ -0,0 +1,140 @@
+// Package netsim wraps an http.RoundTripper to inject simulated network
+// failures and account for raw bandwidth use, so that urlpoll's error
+// back-off (errTimeout*errCount) and network overhead can be validated and
+// measured under controlled, repeatable conditions.
+package netsim
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pattern describes how often RoundTrip should simulate a failure instead
+// of performing the real request. Exactly one of OneInN or BurstSize is
+// set by ParsePattern.
+type Pattern struct {
+	OneInN    int // fail with probability 1/OneInN on each request
+	BurstSize int // fail in bursts of BurstSize requests, then succeed for BurstSize requests, repeating
+}
+
+// ParsePattern parses a failure-injection spec. Accepted forms are
+// "1-in-N" (probabilistic) and "burst-K" (deterministic bursts).
+func ParsePattern(spec string) (*Pattern, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	switch {
+	case strings.HasPrefix(spec, "1-in-"):
+		n, err := strconv.Atoi(strings.TrimPrefix(spec, "1-in-"))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("netsim: invalid pattern %q", spec)
+		}
+		return &Pattern{OneInN: n}, nil
+	case strings.HasPrefix(spec, "burst-"):
+		k, err := strconv.Atoi(strings.TrimPrefix(spec, "burst-"))
+		if err != nil || k <= 0 {
+			return nil, fmt.Errorf("netsim: invalid pattern %q", spec)
+		}
+		return &Pattern{BurstSize: k}, nil
+	default:
+		return nil, fmt.Errorf("netsim: invalid pattern %q, want \"1-in-N\" or \"burst-K\"", spec)
+	}
+}
+
+// Injector is an http.RoundTripper that wraps Next (http.DefaultTransport
+// if nil), simulating failures according to Pattern and tallying the raw
+// bytes of every request and response it sees, headers included.
+type Injector struct {
+	Pattern *Pattern
+	Next    http.RoundTripper
+
+	mu    sync.Mutex
+	calls int
+	rng   *rand.Rand
+
+	bytesSent int64
+	bytesRecv int64
+}
+
+// NewInjector returns an Injector for pattern. A nil pattern disables
+// failure injection but bandwidth is still accounted for. Each Injector
+// is seeded independently (from the current time) so that multiple
+// Resources sharing the same Pattern, e.g. crawl-discovered children,
+// fail on independent poll indices instead of all in lockstep.
+func NewInjector(pattern *Pattern) *Injector {
+	return &Injector{Pattern: pattern, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (i *Injector) RoundTrip(req *http.Request) (*http.Response, error) {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err == nil {
+		atomic.AddInt64(&i.bytesSent, int64(len(dump)))
+	}
+
+	if i.shouldFail() {
+		return nil, fmt.Errorf("netsim: simulated failure for %s", req.URL)
+	}
+
+	next := i.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &countingReadCloser{rc: resp.Body, counter: &i.bytesRecv}
+	if headers, err := httputil.DumpResponse(resp, false); err == nil {
+		atomic.AddInt64(&i.bytesRecv, int64(len(headers)))
+	}
+	return resp, nil
+}
+
+// shouldFail decides, per i.Pattern, whether this call should simulate a
+// failure instead of reaching the network.
+func (i *Injector) shouldFail() bool {
+	if i.Pattern == nil {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls++
+
+	if i.Pattern.BurstSize > 0 {
+		cycle := (i.calls - 1) % (2 * i.Pattern.BurstSize)
+		return cycle < i.Pattern.BurstSize
+	}
+	return i.rng.Intn(i.Pattern.OneInN) == 0
+}
+
+// BytesSent returns the cumulative bytes sent (request line, headers, and
+// body) across every RoundTrip.
+func (i *Injector) BytesSent() int64 { return atomic.LoadInt64(&i.bytesSent) }
+
+// BytesReceived returns the cumulative bytes received (status line,
+// headers, and body) across every RoundTrip.
+func (i *Injector) BytesReceived() int64 { return atomic.LoadInt64(&i.bytesRecv) }
+
+// countingReadCloser tallies every byte read from the wrapped ReadCloser
+// into counter.
+type countingReadCloser struct {
+	rc      io.ReadCloser
+	counter *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.rc.Read(p)
+	atomic.AddInt64(c.counter, int64(n))
+	return n, err
+}
+
+func (c *countingReadCloser) Close() error { return c.rc.Close() }
@@ -0,0 +1,49 @@
+package netsim
+
+import "testing"
+
+func TestParsePattern(t *testing.T) {
+	p, err := ParsePattern("1-in-10")
+	if err != nil {
+		t.Fatalf("ParsePattern: %v", err)
+	}
+	if p.OneInN != 10 || p.BurstSize != 0 {
+		t.Fatalf("ParsePattern(\"1-in-10\") = %+v, want OneInN=10", p)
+	}
+
+	p, err = ParsePattern("burst-5")
+	if err != nil {
+		t.Fatalf("ParsePattern: %v", err)
+	}
+	if p.BurstSize != 5 || p.OneInN != 0 {
+		t.Fatalf("ParsePattern(\"burst-5\") = %+v, want BurstSize=5", p)
+	}
+}
+
+func TestParsePatternInvalid(t *testing.T) {
+	for _, spec := range []string{"", "1-in-0", "1-in-x", "burst-0", "burst-x", "whatever"} {
+		if _, err := ParsePattern(spec); err == nil {
+			t.Errorf("ParsePattern(%q): expected an error, got nil", spec)
+		}
+	}
+}
+
+func TestInjectorShouldFailBurstPattern(t *testing.T) {
+	i := NewInjector(&Pattern{BurstSize: 2})
+
+	want := []bool{true, true, false, false, true, true}
+	for n, w := range want {
+		if got := i.shouldFail(); got != w {
+			t.Errorf("call %d: shouldFail() = %v, want %v", n, got, w)
+		}
+	}
+}
+
+func TestInjectorShouldFailNilPatternNeverFails(t *testing.T) {
+	i := NewInjector(nil)
+	for n := 0; n < 10; n++ {
+		if i.shouldFail() {
+			t.Fatalf("call %d: shouldFail() = true with a nil pattern", n)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package probe implements check semantics and metric collection for
+// urlpoll's Resource polling, and exposes the results over HTTP in
+// Prometheus text exposition format (/metrics) and JSON (/status).
+package probe
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExpectedStatus describes which HTTP status codes count as success for a
+// given Resource, e.g. "2xx" or an explicit list such as "200,301".
+type ExpectedStatus struct {
+	classes []int // accepted hundreds-digit classes, e.g. 2 for "2xx"
+	codes   map[int]bool
+}
+
+// ParseExpectedStatus parses a status spec. Accepted forms are a class
+// shorthand ("2xx", "3xx") or a comma-separated list of explicit codes
+// ("200,301,302"). An empty spec defaults to "2xx".
+func ParseExpectedStatus(spec string) (*ExpectedStatus, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = "2xx"
+	}
+	es := &ExpectedStatus{codes: make(map[int]bool)}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if strings.HasSuffix(part, "xx") && len(part) == 3 {
+			class, err := strconv.Atoi(part[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("probe: invalid status class %q", part)
+			}
+			es.classes = append(es.classes, class)
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("probe: invalid expected status %q", part)
+		}
+		es.codes[code] = true
+	}
+	return es, nil
+}
+
+// Match reports whether code satisfies the expectation.
+func (es *ExpectedStatus) Match(code int) bool {
+	if es.codes[code] {
+		return true
+	}
+	for _, class := range es.classes {
+		if code/100 == class {
+			return true
+		}
+	}
+	return false
+}
+
+// urlMetrics holds the running counters for a single polled URL.
+type urlMetrics struct {
+	statusCode        int
+	consecutiveErrors int
+	totalPolls        int64
+	totalFailures     int64
+	lastLatency       time.Duration
+	lastSuccess       time.Time
+	bytesSent         int64
+	bytesReceived     int64
+}
+
+// Metrics collects per-URL poll outcomes and serves them over HTTP. The
+// zero value is not usable; create one with NewMetrics.
+type Metrics struct {
+	mu   sync.Mutex
+	urls map[string]*urlMetrics
+}
+
+// NewMetrics returns an empty Metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{urls: make(map[string]*urlMetrics)}
+}
+
+// Observe records the outcome of a single poll of url: the latency of the
+// request, the HTTP status code obtained (0 if the request itself failed),
+// and whether the outcome counts as a failure.
+func (m *Metrics) Observe(url string, latency time.Duration, statusCode int, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.urls[url]
+	if !ok {
+		u = &urlMetrics{}
+		m.urls[url] = u
+	}
+	u.statusCode = statusCode
+	u.lastLatency = latency
+	u.totalPolls++
+	if failed {
+		u.consecutiveErrors++
+		u.totalFailures++
+	} else {
+		u.consecutiveErrors = 0
+		u.lastSuccess = time.Now()
+	}
+}
+
+// ObserveBandwidth records cumulative bytes sent and received for url, as
+// measured by a netsim.Injector transport wrapper.
+func (m *Metrics) ObserveBandwidth(url string, bytesSent, bytesReceived int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.urls[url]
+	if !ok {
+		u = &urlMetrics{}
+		m.urls[url] = u
+	}
+	u.bytesSent = bytesSent
+	u.bytesReceived = bytesReceived
+}
+
+// sortedURLs returns the tracked URLs in a stable order, for deterministic
+// exposition output. Callers must hold m.mu.
+func (m *Metrics) sortedURLs() []string {
+	urls := make([]string, 0, len(m.urls))
+	for u := range m.urls {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// ServeHTTP registers the /metrics and /status handlers on mux.
+func (m *Metrics) ServeHTTP(mux *http.ServeMux) {
+	mux.HandleFunc("/metrics", m.handleMetrics)
+	mux.HandleFunc("/status", m.handleStatus)
+}
+
+func (m *Metrics) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP urlpoll_last_status_code Last HTTP status code observed for the URL.")
+	fmt.Fprintln(w, "# TYPE urlpoll_last_status_code gauge")
+	for _, url := range m.sortedURLs() {
+		u := m.urls[url]
+		fmt.Fprintf(w, "urlpoll_last_status_code{url=%q} %d\n", url, u.statusCode)
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_consecutive_errors Number of consecutive failed polls.")
+	fmt.Fprintln(w, "# TYPE urlpoll_consecutive_errors gauge")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_consecutive_errors{url=%q} %d\n", url, m.urls[url].consecutiveErrors)
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_poll_latency_seconds Latency of the most recent poll.")
+	fmt.Fprintln(w, "# TYPE urlpoll_poll_latency_seconds gauge")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_poll_latency_seconds{url=%q} %f\n", url, m.urls[url].lastLatency.Seconds())
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_polls_total Total number of polls performed.")
+	fmt.Fprintln(w, "# TYPE urlpoll_polls_total counter")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_polls_total{url=%q} %d\n", url, m.urls[url].totalPolls)
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_poll_failures_total Total number of failed polls.")
+	fmt.Fprintln(w, "# TYPE urlpoll_poll_failures_total counter")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_poll_failures_total{url=%q} %d\n", url, m.urls[url].totalFailures)
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_bytes_sent_total Cumulative bytes sent, headers included.")
+	fmt.Fprintln(w, "# TYPE urlpoll_bytes_sent_total counter")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_bytes_sent_total{url=%q} %d\n", url, m.urls[url].bytesSent)
+	}
+	fmt.Fprintln(w, "# HELP urlpoll_bytes_received_total Cumulative bytes received, headers included.")
+	fmt.Fprintln(w, "# TYPE urlpoll_bytes_received_total counter")
+	for _, url := range m.sortedURLs() {
+		fmt.Fprintf(w, "urlpoll_bytes_received_total{url=%q} %d\n", url, m.urls[url].bytesReceived)
+	}
+}
+
+func (m *Metrics) handleStatus(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, "[")
+	for i, url := range m.sortedURLs() {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		u := m.urls[url]
+		lastSuccess := ""
+		if !u.lastSuccess.IsZero() {
+			lastSuccess = u.lastSuccess.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, `{"url":%q,"statusCode":%d,"consecutiveErrors":%d,"lastSuccess":%q,"latencyMs":%d,"bytesSent":%d,"bytesReceived":%d}`,
+			url, u.statusCode, u.consecutiveErrors, lastSuccess, u.lastLatency.Milliseconds(), u.bytesSent, u.bytesReceived)
+	}
+	fmt.Fprint(w, "]")
+}
+
+// AlignToInterval returns the time.Duration to sleep from now so that the
+// next wake-up lands on the next boundary of interval, keeping scrape
+// schedules predictable across restarts.
+func AlignToInterval(now time.Time, interval time.Duration) time.Duration {
+	truncated := now.Truncate(interval)
+	next := truncated.Add(interval)
+	return next.Sub(now)
+}
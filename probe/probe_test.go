@@ -0,0 +1,54 @@
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpectedStatus(t *testing.T) {
+	cases := []struct {
+		spec string
+		code int
+		want bool
+	}{
+		{"2xx", 200, true},
+		{"2xx", 204, true},
+		{"2xx", 301, false},
+		{"", 200, true}, // empty spec defaults to 2xx
+		{"200,301,302", 301, true},
+		{"200,301,302", 404, false},
+		{"3xx", 302, true},
+	}
+	for _, c := range cases {
+		es, err := ParseExpectedStatus(c.spec)
+		if err != nil {
+			t.Fatalf("ParseExpectedStatus(%q): %v", c.spec, err)
+		}
+		if got := es.Match(c.code); got != c.want {
+			t.Errorf("ParseExpectedStatus(%q).Match(%d) = %v, want %v", c.spec, c.code, got, c.want)
+		}
+	}
+}
+
+func TestParseExpectedStatusInvalid(t *testing.T) {
+	for _, spec := range []string{"9xx", "abc", "2xx,abc", "x"} {
+		if _, err := ParseExpectedStatus(spec); err == nil {
+			t.Errorf("ParseExpectedStatus(%q): expected an error, got nil", spec)
+		}
+	}
+}
+
+func TestAlignToInterval(t *testing.T) {
+	interval := time.Minute
+	now := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+
+	d := AlignToInterval(now, interval)
+	if d != 30*time.Second {
+		t.Fatalf("AlignToInterval at :30 within a minute = %v, want 30s", d)
+	}
+
+	onBoundary := time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC)
+	if d := AlignToInterval(onBoundary, interval); d != interval {
+		t.Fatalf("AlignToInterval exactly on a boundary = %v, want %v", d, interval)
+	}
+}
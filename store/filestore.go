@@ -0,0 +1,153 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// compactEvery is the number of Save calls between log compactions.
+const compactEvery = 500
+
+// FileStore is a StateStore backed by a plain JSON-lines append log at
+// path, one record per line. Save appends a new line; periodically (every
+// compactEvery writes) the log is compacted down to its latest record per
+// URL to keep it from growing unboundedly, by writing a fresh file and
+// renaming it over the original.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	records map[string]Record
+	writes  int
+}
+
+// NewFileStore opens (or creates) the log at path and replays it to
+// recover the last known Record for each URL.
+func NewFileStore(path string) (*FileStore, error) {
+	records, err := replay(path)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to recover %s: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to open %s: %w", path, err)
+	}
+
+	return &FileStore{path: path, f: f, records: records}, nil
+}
+
+// replay reads path line by line, keeping the most recent record seen
+// for each URL. A missing file is not an error; it means a fresh start.
+func replay(path string) (map[string]Record, error) {
+	records := make(map[string]Record)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue // skip a corrupt line rather than fail recovery entirely
+		}
+		records[r.URL] = r
+	}
+	return records, scanner.Err()
+}
+
+// Load implements StateStore.
+func (s *FileStore) Load() (map[string]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]Record, len(s.records))
+	for url, r := range s.records {
+		out[url] = r
+	}
+	return out, nil
+}
+
+// Save implements StateStore.
+func (s *FileStore) Save(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.records[r.URL] = r
+
+	s.writes++
+	if s.writes >= compactEvery {
+		if err := s.compactLocked(); err != nil {
+			return err
+		}
+		s.writes = 0
+	}
+	return nil
+}
+
+// compactLocked rewrites the log down to one line per URL. Callers must
+// hold s.mu.
+func (s *FileStore) compactLocked() error {
+	tmpPath := s.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, r := range s.records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.f = f
+	return nil
+}
+
+// Close implements StateStore.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreSaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	fs, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	rec := Record{URL: "http://example.com", Status: "200 OK", StatusCode: 200, LastPolled: time.Now().Truncate(time.Second)}
+	if err := fs.Save(rec); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	fs2, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore (reopen): %v", err)
+	}
+	defer fs2.Close()
+
+	records, err := fs2.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, ok := records["http://example.com"]
+	if !ok {
+		t.Fatalf("expected a recovered record for http://example.com, got %+v", records)
+	}
+	if got.StatusCode != 200 || !got.LastPolled.Equal(rec.LastPolled) {
+		t.Fatalf("recovered record = %+v, want %+v", got, rec)
+	}
+}
+
+func TestFileStoreSaveKeepsLatestPerURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	fs, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer fs.Close()
+
+	if err := fs.Save(Record{URL: "http://example.com", StatusCode: 200}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := fs.Save(Record{URL: "http://example.com", StatusCode: 500, ErrCount: 1}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	records, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got := records["http://example.com"]
+	if got.StatusCode != 500 || got.ErrCount != 1 {
+		t.Fatalf("Load() = %+v, want the most recently saved record", got)
+	}
+}
+
+func TestFileStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	fs, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer fs.Close()
+
+	for i := 0; i < compactEvery+1; i++ {
+		if err := fs.Save(Record{URL: "http://example.com", StatusCode: 200, ErrCount: i}); err != nil {
+			t.Fatalf("Save #%d: %v", i, err)
+		}
+	}
+
+	records, err := replay(path)
+	if err != nil {
+		t.Fatalf("replay after compaction: %v", err)
+	}
+	got, ok := records["http://example.com"]
+	if !ok {
+		t.Fatalf("expected the compacted log to still contain http://example.com")
+	}
+	if got.ErrCount != compactEvery {
+		t.Fatalf("compacted record ErrCount = %d, want %d", got.ErrCount, compactEvery)
+	}
+}
+
+func TestFileStoreLoadMissingFileIsEmpty(t *testing.T) {
+	records, err := replay(filepath.Join(t.TempDir(), "does-not-exist.jsonl"))
+	if err != nil {
+		t.Fatalf("replay of a missing file should not error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("replay of a missing file = %+v, want empty", records)
+	}
+}
@@ -0,0 +1,25 @@
+// Package store persists the last-known state of each polled URL so that
+// urlpoll can recover status, error counts, and scheduling on restart
+// instead of starting every URL from a blank slate.
+package store
+
+import "time"
+
+// Record is the last-known state of a single polled URL.
+type Record struct {
+	URL        string    `json:"url"`
+	Status     string    `json:"status"`
+	StatusCode int       `json:"statusCode"`
+	ErrCount   int       `json:"errCount"`
+	LastPolled time.Time `json:"lastPolled"`
+}
+
+// StateStore persists Records across restarts.
+type StateStore interface {
+	// Load returns the last known Record for every URL previously seen.
+	Load() (map[string]Record, error)
+	// Save write-through persists the latest Record for a URL.
+	Save(r Record) error
+	// Close flushes and releases any resources held by the store.
+	Close() error
+}
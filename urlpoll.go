@@ -1,134 +1,668 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"makesitgo/urlpoll/alerts"
+	"makesitgo/urlpoll/config"
+	"makesitgo/urlpoll/crawl"
+	"makesitgo/urlpoll/dashboard"
+	"makesitgo/urlpoll/netsim"
+	"makesitgo/urlpoll/probe"
+	"makesitgo/urlpoll/store"
 )
 
 const (
 	numPollers     = 2                // number of Poller goroutines to launch
-	pollInterval   = 60 * time.Second // how often to poll each URL
+	pollInterval   = 60 * time.Second // default poll interval, if not set per-URL
 	statusInterval = 10 * time.Second // how often to log status to stdout
 	errTimeout     = 10 * time.Second // back-off timeout on error
+	pollTimeout    = 10 * time.Second // default request timeout, if not set per-URL
+
+	crawlHostInterval  = 5 * time.Second // minimum time between crawling two links on the same host
+	crawlMaxBodyBytes  = 1 << 20         // cap on HTML bytes read per page while looking for links
+	crawlQueueCapacity = 1024            // bound on resources queued in pending awaiting a poll, discovered links included
 )
 
-var urlsFilepath = flag.String("urlsFilepath", "", "filepath to .txt file containing urls to poll (each on new line)")
+var (
+	configFilepath   = flag.String("config", "", "filepath to JSON config file describing urls to poll")
+	method           = flag.String("method", "HEAD", "default HTTP method to poll with, if not set per-URL (GET is used instead when -crawlDepth is set)")
+	expectedStatus   = flag.String("expectedStatus", "2xx", "default expected status class or codes, if not set per-URL")
+	metricsAddr      = flag.String("metricsAddr", "", "if set, address to serve /metrics and /status on, e.g. \":9090\"")
+	alignPolls       = flag.Bool("alignPolls", false, "align each poll to a fixed wall-clock interval boundary")
+	statePath        = flag.String("state", "", "if set, filepath to a state store recovering status across restarts")
+	tui              = flag.Bool("tui", false, "show a live terminal dashboard instead of periodic log output")
+	simulateFailures = flag.String("simulateFailures", "", "if set, inject simulated failures per this pattern, e.g. \"1-in-10\" or \"burst-5\"")
+	crawlDepth       = flag.Int("crawlDepth", 0, "if set above 0, follow <a href> links from successful HTML polls up to this many hops")
+)
 
 // State represents the last-known state of a URL.
 type State struct {
-	url    string
-	status string
+	url        string
+	status     string
+	statusCode int
+	latency    time.Duration
+	failed     bool
+	errCount   int
+	interval   time.Duration
+	depth      int    // hops from an original configured URL, via -crawlDepth
+	parent     string // URL this one was discovered from, if any
+}
+
+// broadcaster fans out State updates to any number of subscribers, e.g.
+// a dashboard, without making StateMonitor's map the single owner of
+// that data. A slow or absent subscriber never blocks publishing: sends
+// are non-blocking and simply drop if a subscriber's buffer is full.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs []chan State
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{}
+}
+
+// subscribe returns a new channel that receives every subsequent publish.
+func (b *broadcaster) subscribe() <-chan State {
+	ch := make(chan State, 32)
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broadcaster) publish(s State) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// trackedState is StateMonitor's bookkeeping for transition detection.
+type trackedState struct {
+	class             alerts.Class
+	consecutiveErrors int
 }
 
 // StateMonitor maintains a map that stores the state of the URLs being
-// polled, and prints the current state every updateInterval nanoseconds.
-// It returns a chan State to which resource state should be sent.
-func StateMonitor(updateInterval time.Duration) chan<- State {
+// polled. If logToStdout is set, it prints the current state every
+// updateInterval nanoseconds (disable this when a dashboard is rendering
+// the same information instead). It also watches for OK<->ERROR
+// transitions (and, if sustainedErrorEvery is positive, sustained-error
+// escalations) and forwards them to transitions. recovered seeds the
+// transition-tracking state from a prior run's persisted state, if any,
+// so the first poll after a restart of a URL that was already in ERROR
+// isn't mistaken for a fresh transition and doesn't fire a spurious
+// alert. If stateStore is non-nil, every update is written through to it
+// so state survives a restart. If broadcast is non-nil, every update is
+// published to it so other subscribers (e.g. a dashboard) can observe
+// state without owning the map. It returns a chan State to which
+// resource state should be sent.
+func StateMonitor(updateInterval time.Duration, transitions chan<- alerts.Transition, sustainedErrorEvery int, stateStore store.StateStore, recovered map[string]store.Record, broadcast *broadcaster, logToStdout bool) chan<- State {
 	updates := make(chan State)
-	urlStatus := make(map[string]string)
+	urlStatus := make(map[string]State)
+	tracked := seedTracked(recovered)
 	ticker := time.NewTicker(updateInterval)
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				logState(urlStatus)
+				if logToStdout {
+					logState(urlStatus)
+				}
 			case s := <-updates:
-				urlStatus[s.url] = s.status
+				urlStatus[s.url] = s
+				trackTransition(s, tracked, transitions, sustainedErrorEvery)
+				if stateStore != nil {
+					if err := stateStore.Save(store.Record{
+						URL:        s.url,
+						Status:     s.status,
+						StatusCode: s.statusCode,
+						ErrCount:   s.errCount,
+						LastPolled: time.Now(),
+					}); err != nil {
+						log.Println("store: failed to save", s.url, err)
+					}
+				}
+				if broadcast != nil {
+					broadcast.publish(s)
+				}
 			}
 		}
 	}()
 	return updates
 }
 
-// logState prints a state map.
-func logState(s map[string]string) {
+// seedTracked builds the initial tracked map for StateMonitor from
+// recovered state-store records, so a restart doesn't treat a URL's
+// already-known ERROR status as a fresh Unknown->ERROR transition. A
+// Record's ErrCount is what Poll last left it at, so a positive count
+// means it was last seen failing.
+func seedTracked(recovered map[string]store.Record) map[string]trackedState {
+	tracked := make(map[string]trackedState, len(recovered))
+	for url, rec := range recovered {
+		if rec.ErrCount > 0 {
+			tracked[url] = trackedState{class: alerts.Error, consecutiveErrors: rec.ErrCount}
+		} else {
+			tracked[url] = trackedState{class: alerts.OK}
+		}
+	}
+	return tracked
+}
+
+// trackTransition updates tracked with s's outcome and, if it amounts to
+// a state change (or a sustained-error escalation), sends a Transition.
+func trackTransition(s State, tracked map[string]trackedState, transitions chan<- alerts.Transition, sustainedErrorEvery int) {
+	class := alerts.OK
+	if s.failed {
+		class = alerts.Error
+	}
+
+	prev := tracked[s.url]
+	next := trackedState{class: class}
+
+	switch {
+	case class != prev.class:
+		if class == alerts.Error {
+			next.consecutiveErrors = 1
+		}
+		// prev.class == Unknown means this is the URL's first-ever
+		// poll (not a recovered one, which seedTracked already gave a
+		// real class): that's the program starting up, not a state
+		// change, so don't fire a transition for it.
+		if prev.class != alerts.Unknown {
+			notify(transitions, s.url, prev.class, class, next.consecutiveErrors)
+		}
+	case class == alerts.Error:
+		next.consecutiveErrors = prev.consecutiveErrors + 1
+		if sustainedErrorEvery > 0 && next.consecutiveErrors%sustainedErrorEvery == 0 {
+			notify(transitions, s.url, prev.class, class, next.consecutiveErrors)
+		}
+	}
+
+	tracked[s.url] = next
+}
+
+func notify(transitions chan<- alerts.Transition, url string, from, to alerts.Class, consecutiveErrors int) {
+	if transitions == nil {
+		return
+	}
+	transitions <- alerts.Transition{
+		URL:               url,
+		From:              from,
+		To:                to,
+		ConsecutiveErrors: consecutiveErrors,
+		Time:              time.Now(),
+	}
+}
+
+// logState prints a state map, indenting each discovered URL by its crawl
+// depth and naming the parent it was discovered from. Entries are printed
+// in map iteration order, so a URL is not necessarily printed directly
+// below its parent.
+func logState(s map[string]State) {
 	log.Println("Current state:")
-	for k, v := range s {
-		log.Printf(" %s %s", k, v)
+	for url, st := range s {
+		indent := strings.Repeat("  ", st.depth)
+		if st.parent != "" {
+			log.Printf(" %s%s %s (discovered from %s)", indent, url, st.status, st.parent)
+		} else {
+			log.Printf(" %s%s %s", indent, url, st.status)
+		}
 	}
 }
 
 // Resource represents and HTTP URL to be polled by this program.
 type Resource struct {
-	url      string
-	errCount int
+	url        string
+	method     string
+	expected   *probe.ExpectedStatus
+	interval   time.Duration
+	timeout    time.Duration
+	errCount   int
+	statusCode int
+	client     *http.Client     // nil means use http.DefaultClient
+	injector   *netsim.Injector // non-nil when -simulateFailures is set
+	pattern    *netsim.Pattern  // the Pattern injector was built from, so a discovered child can get its own Injector rather than sharing this one
+	crawler    *crawl.Crawler   // non-nil when -crawlDepth is set
+	depth      int              // hops from an original configured URL
+	parent     string           // URL this one was discovered from, if any
 }
 
-// Poll executes an HTTP HEAD request for url
-// and returns the HTTP status string or an error string.
-func (r *Resource) Poll() string {
-	resp, err := http.Head(r.url)
+// httpClient returns the client to poll with, defaulting to
+// http.DefaultClient when r.client is unset.
+func (r *Resource) httpClient() *http.Client {
+	if r.client != nil {
+		return r.client
+	}
+	return http.DefaultClient
+}
+
+// Poll executes an HTTP request for url using r.method, asserts the
+// response status against r.expected, and returns the HTTP status string
+// or an error string. Outcomes are recorded on metrics if non-nil. If r
+// has a crawler, a successful HTML response also yields the links
+// discovered on the page.
+func (r *Resource) Poll(metrics *probe.Metrics) (status string, failed bool, links []crawl.Link) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, nil)
 	if err != nil {
 		log.Println("Error", r.url, err)
 		r.errCount++
-		return err.Error()
+		r.statusCode = 0
+		if metrics != nil {
+			metrics.Observe(r.url, time.Since(start), 0, true)
+		}
+		return err.Error(), true, nil
+	}
+
+	resp, err := r.httpClient().Do(req)
+	if err != nil {
+		log.Println("Error", r.url, err)
+		r.errCount++
+		r.statusCode = 0
+		if metrics != nil {
+			metrics.Observe(r.url, time.Since(start), 0, true)
+			r.reportBandwidth(metrics)
+		}
+		return err.Error(), true, nil
+	}
+	defer resp.Body.Close()
+
+	r.statusCode = resp.StatusCode
+	failed = !r.expected.Match(resp.StatusCode)
+	if failed {
+		log.Println("Error", r.url, "unexpected status", resp.Status)
+		r.errCount++
+	} else {
+		r.errCount = 0
+		links = r.discoverLinks(resp)
+	}
+	if metrics != nil {
+		metrics.Observe(r.url, time.Since(start), resp.StatusCode, failed)
+		r.reportBandwidth(metrics)
 	}
-	r.errCount = 0
-	return resp.Status
+	return resp.Status, failed, links
+}
+
+// discoverLinks parses resp's body for links, if r has a crawler and the
+// response looks like HTML. Parse errors are logged and otherwise ignored
+// since they shouldn't fail the poll itself.
+func (r *Resource) discoverLinks(resp *http.Response) []crawl.Link {
+	if r.crawler == nil || !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil
+	}
+	links, err := r.crawler.Discover(r.url, r.depth, io.LimitReader(resp.Body, crawlMaxBodyBytes))
+	if err != nil {
+		log.Println("crawl: failed to parse", r.url, err)
+		return nil
+	}
+	return links
+}
+
+// reportBandwidth forwards r's cumulative bandwidth, as tracked by its
+// netsim.Injector, to metrics. It is a no-op if failure injection isn't
+// enabled for r.
+func (r *Resource) reportBandwidth(metrics *probe.Metrics) {
+	if r.injector == nil {
+		return
+	}
+	metrics.ObserveBandwidth(r.url, r.injector.BytesSent(), r.injector.BytesReceived())
 }
 
 // Sleep sleeps for an appropriate interval (dependent on error state)
-// before sending the Resource to done.
-func (r *Resource) Sleep(done chan<- *Resource) {
-	time.Sleep(pollInterval + errTimeout*time.Duration(r.errCount))
+// before sending the Resource to done. If align is set, r.interval is
+// replaced by the time left until the next wall-clock boundary of
+// r.interval, so error-free polls land on that boundary and scrape
+// schedules stay predictable; any error back-off is added on top of it,
+// so a failing Resource wakes up off the boundary.
+func (r *Resource) Sleep(done chan<- *Resource, align bool) {
+	d := r.interval + errTimeout*time.Duration(r.errCount)
+	if align {
+		d = probe.AlignToInterval(time.Now(), r.interval) + errTimeout*time.Duration(r.errCount)
+	}
+	time.Sleep(d)
 	done <- r
 }
 
-// Poller receives a Resource from in, records the status of its URL in status,
-// and then releases the Resource back through out
-func Poller(in <-chan *Resource, out chan<- *Resource, status chan<- State) {
+// feedDashboard translates broadcast State updates into dashboard.Updates
+// until sub is closed.
+func feedDashboard(sub <-chan State, dash *dashboard.Dashboard) {
+	for s := range sub {
+		dash.Observe(dashboard.Update{
+			URL:        s.url,
+			Status:     s.status,
+			StatusCode: s.statusCode,
+			Failed:     s.failed,
+			ErrCount:   s.errCount,
+			NextPollAt: time.Now().Add(s.interval + errTimeout*time.Duration(s.errCount)),
+		})
+	}
+}
+
+// Poller receives a Resource from in, records the status of its URL in
+// status, releases the Resource back through out, and enqueues any links
+// discovered by crawling onto discovered. A discovered Resource is
+// dropped, rather than blocking, if discovered's queue is full.
+func Poller(in <-chan *Resource, out chan<- *Resource, discovered chan<- *Resource, status chan<- State, metrics *probe.Metrics) {
 	for r := range in {
-		s := r.Poll()
-		status <- State{r.url, s}
+		start := time.Now()
+		s, failed, links := r.Poll(metrics)
+		status <- State{
+			url:        r.url,
+			status:     s,
+			statusCode: r.statusCode,
+			latency:    time.Since(start),
+			failed:     failed,
+			errCount:   r.errCount,
+			interval:   r.interval,
+			depth:      r.depth,
+			parent:     r.parent,
+		}
+		for _, link := range links {
+			enqueueDiscovered(r, link, discovered)
+		}
 		out <- r
 	}
 }
 
-// Sender reads urls from provided filepath and sends them as Resources
-// to the queue receiving poll requests
-func Sender(urlsFilepath string, todo chan<- *Resource) {
-	urlsFile, err := os.Open(urlsFilepath)
-	if err != nil {
-		log.Fatalln("failed to read urls from file", urlsFilepath, err)
-		return
+// enqueueDiscovered builds a Resource for a crawl.Link, inheriting poll
+// settings from the Resource it was discovered on, and sends it to
+// discovered without blocking. If parent has a failure-injection pattern,
+// the child gets its own Injector built from that same Pattern rather
+// than sharing parent's, so bandwidth accounting and injected-failure
+// state stay per-URL instead of pooling across the whole crawl subtree.
+func enqueueDiscovered(parent *Resource, link crawl.Link, discovered chan<- *Resource) {
+	child := &Resource{
+		url:      link.URL,
+		method:   parent.method,
+		expected: parent.expected,
+		interval: parent.interval,
+		timeout:  parent.timeout,
+		pattern:  parent.pattern,
+		crawler:  parent.crawler,
+		depth:    link.Depth,
+		parent:   link.Parent,
+	}
+	if parent.pattern != nil {
+		child.injector = netsim.NewInjector(parent.pattern)
+		child.client = &http.Client{Transport: child.injector}
+	}
+	select {
+	case discovered <- child:
+	default:
+		log.Println("crawl: queue full, dropping discovered link", child.url)
+	}
+}
+
+// newResource builds a Resource from a URLConfig entry, falling back to
+// the given defaults for any field the entry leaves unset.
+func newResource(u config.URLConfig, defaultMethod string, defaultExpected *probe.ExpectedStatus, recovered *store.Record, failurePattern *netsim.Pattern, crawler *crawl.Crawler) (*Resource, error) {
+	method := u.Method
+	switch {
+	case method != "":
+		// an explicit per-URL method always wins.
+	case crawler != nil:
+		// discoverLinks needs a response body to parse, which a HEAD
+		// request never has; force GET rather than silently never
+		// discovering any links.
+		method = http.MethodGet
+	default:
+		method = defaultMethod
+	}
+
+	expected := defaultExpected
+	if u.ExpectedStatus != "" {
+		var err error
+		expected, err = probe.ParseExpectedStatus(u.ExpectedStatus)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	interval := time.Duration(u.Interval)
+	if interval <= 0 {
+		interval = pollInterval
+	}
+	timeout := time.Duration(u.Timeout)
+	if timeout <= 0 {
+		timeout = pollTimeout
+	}
+
+	r := &Resource{
+		url:      u.URL,
+		method:   method,
+		expected: expected,
+		interval: interval,
+		timeout:  timeout,
+	}
+	if recovered != nil {
+		r.errCount = recovered.ErrCount
+		r.statusCode = recovered.StatusCode
+	}
+	r.pattern = failurePattern
+	if failurePattern != nil {
+		r.injector = netsim.NewInjector(failurePattern)
+		r.client = &http.Client{Transport: r.injector}
+	}
+	r.crawler = crawler
+	return r, nil
+}
+
+// initialDelay returns how long to wait before the first poll of a
+// recovered Resource, so that a restart doesn't re-poll every URL at once.
+// It schedules the next poll for when it would naturally have fallen due
+// (lastPolled + interval + back-off), or immediately if that time has
+// already passed.
+func initialDelay(r *Resource, recovered *store.Record) time.Duration {
+	if recovered == nil || recovered.LastPolled.IsZero() {
+		return 0
+	}
+	due := recovered.LastPolled.Add(r.interval + errTimeout*time.Duration(r.errCount))
+	if d := time.Until(due); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Sender builds a Resource for each configured URL, seeded from recovered
+// state if available, and sends it to the queue receiving poll requests.
+// Resources recovered from a recent poll are staggered by initialDelay
+// rather than sent immediately, to avoid a thundering-herd on restart.
+func Sender(cfg *config.Config, defaultMethod string, defaultExpected *probe.ExpectedStatus, recovered map[string]store.Record, failurePattern *netsim.Pattern, crawler *crawl.Crawler, todo chan<- *Resource) {
+	for _, u := range cfg.URLs {
+		var rec *store.Record
+		if recovered != nil {
+			if r, ok := recovered[u.URL]; ok {
+				rec = &r
+			}
+		}
+
+		r, err := newResource(u, defaultMethod, defaultExpected, rec, failurePattern, crawler)
+		if err != nil {
+			log.Fatalln("failed to configure", u.URL, err)
+			return
+		}
+
+		if delay := initialDelay(r, rec); delay > 0 {
+			go func(r *Resource, delay time.Duration) {
+				time.Sleep(delay)
+				todo <- r
+			}(r, delay)
+			continue
+		}
+		todo <- r
+	}
+}
+
+// newNotifier builds the Notifier described by a. It trusts that a has
+// already passed config.Load's validation, so a's sub-config matching
+// Type is non-nil.
+func newNotifier(a config.AlertConfig) (alerts.Notifier, error) {
+	switch a.Type {
+	case "webhook":
+		return &alerts.WebhookNotifier{URL: a.Webhook.URL}, nil
+	case "smtp":
+		return &alerts.SMTPNotifier{
+			Addr: a.SMTP.Addr,
+			Auth: alerts.NewSMTPAuth(a.SMTP.Addr, a.SMTP.Username, a.SMTP.Password),
+			From: a.SMTP.From,
+			To:   a.SMTP.To,
+		}, nil
+	case "exec":
+		return &alerts.ExecNotifier{Command: a.Exec.Command, Args: a.Exec.Args}, nil
+	default:
+		return nil, fmt.Errorf("unknown alert type %q for %q", a.Type, a.Name)
+	}
+}
+
+// buildDispatcher wires up a Dispatcher from cfg's alert sinks and routes.
+func buildDispatcher(cfg *config.Config) (*alerts.Dispatcher, error) {
+	notifiers := make(map[string]alerts.Notifier, len(cfg.Alerts))
+	for _, a := range cfg.Alerts {
+		n, err := newNotifier(a)
+		if err != nil {
+			return nil, err
+		}
+		notifiers[a.Name] = n
 	}
-	defer urlsFile.Close()
 
-	for scanner := bufio.NewScanner(urlsFile); scanner.Scan(); {
-		todo <- &Resource{url: scanner.Text()}
+	dispatcher := alerts.NewDispatcher()
+	for _, u := range cfg.URLs {
+		for _, name := range u.Alerts {
+			n, ok := notifiers[name]
+			if !ok {
+				return nil, fmt.Errorf("alert sink %q referenced by %q is not defined", name, u.URL)
+			}
+			dispatcher.Route(u.URL, n)
+		}
 	}
+	return dispatcher, nil
 }
 
 func main() {
 	// Parse command-line flags
 	flag.Parse()
 
-	// Validate urls filepath
-	if *urlsFilepath == "" {
-		log.Fatalln("failed to provide valid urls filepath")
+	// Validate config filepath
+	if *configFilepath == "" {
+		log.Fatalln("failed to provide valid config filepath")
 		return
 	}
 
-	// Create our input and output channels
-	pending, complete := make(chan *Resource), make(chan *Resource)
+	cfg, err := config.Load(*configFilepath)
+	if err != nil {
+		log.Fatalln(err)
+		return
+	}
 
-	// Launch the StateMonitor
-	status := StateMonitor(statusInterval)
+	defaultExpected, err := probe.ParseExpectedStatus(*expectedStatus)
+	if err != nil {
+		log.Fatalln(err)
+		return
+	}
+
+	dispatcher, err := buildDispatcher(cfg)
+	if err != nil {
+		log.Fatalln(err)
+		return
+	}
+
+	var failurePattern *netsim.Pattern
+	if *simulateFailures != "" {
+		failurePattern, err = netsim.ParsePattern(*simulateFailures)
+		if err != nil {
+			log.Fatalln(err)
+			return
+		}
+	}
+
+	var stateStore store.StateStore
+	var recovered map[string]store.Record
+	if *statePath != "" {
+		fileStore, err := store.NewFileStore(*statePath)
+		if err != nil {
+			log.Fatalln(err)
+			return
+		}
+		defer fileStore.Close()
+		stateStore = fileStore
+
+		recovered, err = stateStore.Load()
+		if err != nil {
+			log.Fatalln(err)
+			return
+		}
+	}
+
+	var crawler *crawl.Crawler
+	if *crawlDepth > 0 {
+		crawler = crawl.NewCrawler(*crawlDepth, crawlHostInterval)
+		urls := make([]string, len(cfg.URLs))
+		for i, u := range cfg.URLs {
+			urls[i] = u.URL
+		}
+		crawler.Seed(urls)
+	}
+
+	// Create our input and output channels. pending is given a bounded
+	// buffer so that links discovered while crawling can be enqueued
+	// without blocking the Poller that found them; once it's full,
+	// further discoveries are dropped rather than growing unbounded.
+	pending, complete := make(chan *Resource, crawlQueueCapacity), make(chan *Resource)
+
+	// Launch the alert dispatcher
+	transitions := make(chan alerts.Transition, 64)
+	go dispatcher.Run(transitions)
+
+	// Launch the StateMonitor. A dashboard, if enabled, replaces the
+	// periodic log dump and instead taps a broadcast subscription.
+	bc := newBroadcaster()
+	status := StateMonitor(statusInterval, transitions, cfg.SustainedErrorIntervals, stateStore, recovered, bc, !*tui)
+
+	if *tui {
+		dash := dashboard.New()
+		sub := bc.subscribe()
+		go feedDashboard(sub, dash)
+		go dash.Run(500*time.Millisecond, nil)
+	}
+
+	// Launch the metrics collector and, if configured, its HTTP endpoint
+	metrics := probe.NewMetrics()
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		metrics.ServeHTTP(mux)
+		go func() {
+			log.Fatalln(http.ListenAndServe(*metricsAddr, mux))
+		}()
+	}
 
 	// Launch some Poller goroutines
 	for i := 0; i < numPollers; i++ {
-		go Poller(pending, complete, status)
+		go Poller(pending, complete, pending, status, metrics)
 	}
 
 	// Send some Resources to the pending queue
-	go Sender(*urlsFilepath, pending)
+	go Sender(cfg, *method, defaultExpected, recovered, failurePattern, crawler, pending)
 
 	// Re-deliver polled Resources back to pending queue after sleep duration
 	for r := range complete {
-		go r.Sleep(pending)
+		go r.Sleep(pending, *alignPolls)
 	}
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	"makesitgo/urlpoll/alerts"
+	"makesitgo/urlpoll/store"
+)
+
+func TestSeedTracked(t *testing.T) {
+	recovered := map[string]store.Record{
+		"http://ok.example.com":  {ErrCount: 0},
+		"http://err.example.com": {ErrCount: 3},
+	}
+
+	tracked := seedTracked(recovered)
+
+	if got := tracked["http://ok.example.com"]; got.class != alerts.OK || got.consecutiveErrors != 0 {
+		t.Errorf("ok.example.com: got %+v, want class=OK consecutiveErrors=0", got)
+	}
+	if got := tracked["http://err.example.com"]; got.class != alerts.Error || got.consecutiveErrors != 3 {
+		t.Errorf("err.example.com: got %+v, want class=ERROR consecutiveErrors=3", got)
+	}
+}
+
+// TestTrackTransitionSkipsFirstPoll verifies that a URL's very first poll
+// (no recovered state, so tracked starts at the zero value) never fires a
+// transition, regardless of whether it succeeds or fails: prev.class is
+// Unknown, which is program startup, not a real state change.
+func TestTrackTransitionSkipsFirstPoll(t *testing.T) {
+	for _, failed := range []bool{false, true} {
+		tracked := map[string]trackedState{}
+		transitions := make(chan alerts.Transition, 1)
+
+		trackTransition(State{url: "http://example.com", failed: failed}, tracked, transitions, 0)
+
+		select {
+		case tr := <-transitions:
+			t.Errorf("failed=%v: unexpected transition on first poll: %+v", failed, tr)
+		default:
+		}
+	}
+}
+
+// TestTrackTransitionFiresOnRealChange verifies that a genuine OK->ERROR
+// transition, for a URL already tracked, is reported.
+func TestTrackTransitionFiresOnRealChange(t *testing.T) {
+	tracked := map[string]trackedState{"http://example.com": {class: alerts.OK}}
+	transitions := make(chan alerts.Transition, 1)
+
+	trackTransition(State{url: "http://example.com", failed: true}, tracked, transitions, 0)
+
+	select {
+	case tr := <-transitions:
+		if tr.From != alerts.OK || tr.To != alerts.Error || tr.ConsecutiveErrors != 1 {
+			t.Fatalf("got %+v, want OK->ERROR with ConsecutiveErrors=1", tr)
+		}
+	default:
+		t.Fatal("expected a transition, got none")
+	}
+	if got := tracked["http://example.com"]; got.class != alerts.Error || got.consecutiveErrors != 1 {
+		t.Fatalf("tracked state = %+v, want class=ERROR consecutiveErrors=1", got)
+	}
+}
+
+// TestTrackTransitionRecoveredErrorDoesNotRefire verifies that a URL
+// recovered from the state store already in ERROR doesn't fire a
+// transition on its first post-restart poll when it's still failing,
+// since that's a continuation, not a change.
+func TestTrackTransitionRecoveredErrorDoesNotRefire(t *testing.T) {
+	tracked := seedTracked(map[string]store.Record{"http://example.com": {ErrCount: 2}})
+	transitions := make(chan alerts.Transition, 1)
+
+	trackTransition(State{url: "http://example.com", failed: true}, tracked, transitions, 0)
+
+	select {
+	case tr := <-transitions:
+		t.Fatalf("unexpected transition for a continuing error: %+v", tr)
+	default:
+	}
+	if got := tracked["http://example.com"]; got.consecutiveErrors != 3 {
+		t.Fatalf("consecutiveErrors = %d, want 3", got.consecutiveErrors)
+	}
+}
+
+// TestTrackTransitionSustainedError verifies that a sustained-error
+// escalation notifies every sustainedErrorEvery consecutive failures,
+// without the class actually changing.
+func TestTrackTransitionSustainedError(t *testing.T) {
+	tracked := map[string]trackedState{"http://example.com": {class: alerts.Error, consecutiveErrors: 2}}
+	transitions := make(chan alerts.Transition, 1)
+
+	trackTransition(State{url: "http://example.com", failed: true}, tracked, transitions, 3)
+
+	select {
+	case tr := <-transitions:
+		if tr.ConsecutiveErrors != 3 {
+			t.Fatalf("got ConsecutiveErrors=%d, want 3", tr.ConsecutiveErrors)
+		}
+	default:
+		t.Fatal("expected a sustained-error transition, got none")
+	}
+}